@@ -0,0 +1,70 @@
+// Copyright © 2023 Timothy E. Peoples
+
+//go:build darwin
+
+package peercred
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// Conn is a net.Conn containing the process credentials for the client
+// side of a Unix domain socket connection.
+type Conn struct {
+	// Creds holds the portable PID, UID and GID of the peer process.
+	Creds Creds
+
+	net.Conn
+}
+
+// newConn wraps conn in a *Conn, populating Creds via the getpeereid(2)
+// mechanism (SOL_LOCAL/LOCAL_PEERCRED, for Uid/Gid) and SOL_LOCAL/
+// LOCAL_PEERPID (for Pid) when conn is a *net.UnixConn.
+func newConn(conn net.Conn) (*Conn, error) {
+	pcc := &Conn{Conn: conn, Creds: Creds{Pid: -1, Uid: -1, Gid: -1}}
+
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return pcc, nil
+	}
+
+	rc, err := uc.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var xucred *unix.Xucred
+	cerr := rc.Control(func(fd uintptr) {
+		xucred, err = unix.GetsockoptXucred(int(fd), unix.SOL_LOCAL, unix.LOCAL_PEERCRED)
+		if err != nil {
+			return
+		}
+
+		if pid, perr := unix.GetsockoptInt(int(fd), unix.SOL_LOCAL, unix.LOCAL_PEERPID); perr == nil {
+			pcc.Creds.Pid = int32(pid)
+		}
+	})
+
+	if cerr != nil || err != nil {
+		if err == nil {
+			err = cerr
+		}
+		return nil, err
+	}
+
+	pcc.Creds.Uid = int32(xucred.Uid)
+	if xucred.Ngroups > 0 {
+		pcc.Creds.Gid = int32(xucred.Groups[0])
+	}
+
+	return pcc, nil
+}
+
+// PeerSecurityLabel always returns a wrapped ErrNotSupported on Darwin,
+// which has no SO_PEERSEC equivalent.
+func (c *Conn) PeerSecurityLabel() (string, error) {
+	return "", fmt.Errorf("peercred: peer security label: %w", ErrNotSupported)
+}
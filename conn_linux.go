@@ -0,0 +1,97 @@
+// Copyright © 2023 Timothy E. Peoples
+
+//go:build linux
+
+package peercred
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// Ucred is a Linux-only alias for golang.org/x/sys/unix.Ucred, kept here so
+// existing code referencing peercred.Ucred continues to compile. New code
+// should prefer the portable Creds type instead.
+type Ucred = unix.Ucred
+
+// Conn is a net.Conn containing the process credentials for the client
+// side of a Unix domain socket connection.
+type Conn struct {
+	// Creds holds the portable PID, UID and GID of the peer process.
+	Creds Creds
+
+	// Ucred is the raw credential structure obtained via SO_PEERCRED.
+	//
+	// Deprecated: use Creds instead. Ucred is only populated on Linux.
+	Ucred *unix.Ucred
+
+	net.Conn
+}
+
+// newConn wraps conn in a *Conn, populating Creds (and, on Linux, Ucred)
+// via SO_PEERCRED when conn is a *net.UnixConn.
+func newConn(conn net.Conn) (*Conn, error) {
+	pcc := &Conn{Conn: conn, Creds: Creds{Pid: -1, Uid: -1, Gid: -1}}
+
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return pcc, nil
+	}
+
+	rc, err := uc.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var ucred *unix.Ucred
+	cerr := rc.Control(func(fd uintptr) {
+		ucred, err = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+
+	if cerr != nil || err != nil {
+		if err == nil {
+			err = cerr
+		}
+		return nil, err
+	}
+
+	pcc.Ucred = ucred
+	pcc.Creds = Creds{Pid: ucred.Pid, Uid: int32(ucred.Uid), Gid: int32(ucred.Gid)}
+
+	return pcc, nil
+}
+
+// PeerSecurityLabel returns the peer's SELinux/AppArmor/Smack security
+// label via SO_PEERSEC. It returns a wrapped ErrNotSupported if the
+// underlying connection isn't a *net.UnixConn, or if the running kernel or
+// active LSM doesn't populate SO_PEERSEC.
+func (c *Conn) PeerSecurityLabel() (string, error) {
+	uc, ok := c.Conn.(*net.UnixConn)
+	if !ok {
+		return "", fmt.Errorf("peercred: peer security label: %w", ErrNotSupported)
+	}
+
+	rc, err := uc.SyscallConn()
+	if err != nil {
+		return "", err
+	}
+
+	var label string
+	cerr := rc.Control(func(fd uintptr) {
+		label, err = unix.GetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_PEERSEC)
+	})
+
+	if cerr != nil {
+		return "", cerr
+	}
+	if err != nil {
+		if err == unix.EOPNOTSUPP || err == unix.ENOPROTOOPT {
+			return "", fmt.Errorf("peercred: peer security label: %w", ErrNotSupported)
+		}
+		return "", err
+	}
+
+	return label, nil
+}
@@ -0,0 +1,24 @@
+// Copyright © 2023 Timothy E. Peoples
+
+package peercred
+
+import "errors"
+
+// ErrNotSupported is returned, possibly wrapped, by platform- or
+// kernel-dependent operations such as (*Conn).PeerSecurityLabel when the
+// underlying mechanism isn't available.
+var ErrNotSupported = errors.New("peercred: not supported")
+
+// Creds is a portable representation of a Unix domain peer's process
+// credentials. It's populated by the OS-specific code backing Listener
+// and FromUnixConn on Linux, Darwin and FreeBSD.
+//
+// Fields that can't be determined on a given platform are set to -1. In
+// particular, Pid is always -1 on FreeBSD since getpeereid(2) -- the only
+// credential-lookup mechanism available there -- doesn't expose the peer's
+// PID.
+type Creds struct {
+	Pid int32
+	Uid int32
+	Gid int32
+}
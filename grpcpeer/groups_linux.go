@@ -0,0 +1,48 @@
+// Copyright © 2023 Timothy E. Peoples
+
+//go:build linux
+
+package grpcpeer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// supplementaryGIDs returns the supplementary group IDs of the process
+// identified by pid, read from the "Groups:" line of /proc/<pid>/status.
+func supplementaryGIDs(pid int32) ([]uint32, error) {
+	if pid < 0 {
+		return nil, nil
+	}
+
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "Groups:") {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, "Groups:"))
+		gids := make([]uint32, 0, len(fields))
+		for _, f := range fields {
+			gid, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(gid))
+		}
+		return gids, nil
+	}
+
+	return nil, sc.Err()
+}
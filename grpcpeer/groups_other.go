@@ -0,0 +1,11 @@
+// Copyright © 2023 Timothy E. Peoples
+
+//go:build !linux
+
+package grpcpeer
+
+// supplementaryGIDs isn't supported outside Linux; callers fall back to
+// checking only the peer's primary Gid against Policy.AllowedGIDs.
+func supplementaryGIDs(pid int32) ([]uint32, error) {
+	return nil, nil
+}
@@ -52,7 +52,7 @@
 //
 //      func (s *svcImpl) SomeMethod(ctx context.Context, req *SomeRequest, opts ...grpc.CallOption) (*SomeResponse, error) {
 //          creds, err := grpcpeer.FromContext(ctx)
-//          // (Unless there's an error) 'creds' now holds a *unix.Ucred
+//          // (Unless there's an error) 'creds' now holds a peercred.Creds
 //          // containing the PID, UID and GID of the calling client process.
 //      }
 //
@@ -64,8 +64,6 @@ import (
 	"errors"
 	"net"
 
-	"golang.org/x/sys/unix"
-
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
@@ -126,9 +124,13 @@ func (pc *peerCredentials) ClientHandshake(ctx context.Context, authority string
 // interface from package google.golang.org/grpc/credentials.
 func (pc *peerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
 	ci := new(credInfo)
-	// First, capture Ucred from conn (if possible)
+	// First, capture Creds from conn (if possible)
 	if pcConn, ok := conn.(*peercred.Conn); ok {
-		ci.ucred = pcConn.Ucred
+		ci.creds = pcConn.Creds
+		ci.hasCreds = true
+		if label, err := pcConn.PeerSecurityLabel(); err == nil {
+			ci.securityLabel = label
+		}
 	}
 
 	// If we have no underlying TransportCredentials, we're done.
@@ -182,13 +184,15 @@ func (pc *peerCredentials) OverrideServerName(s string) error {
 	return pc.tcreds.OverrideServerName(s)
 }
 
-// credInfo is a wrapper around the Ucred struct from golang.org/x/sys/unix
-// allowing it to be used as the AuthInfo member of a gRPC peer.
+// credInfo is a wrapper around peercred.Creds allowing it to be used as
+// the AuthInfo member of a gRPC peer.
 //
-// This is part of the mechanism used for plumbing *Ucred values through
+// This is part of the mechanism used for plumbing Creds values through
 // the gRPC framework and is not intended for general use.
 type credInfo struct {
-	ucred *unix.Ucred
+	creds         peercred.Creds
+	hasCreds      bool // false if the conn wasn't a *peercred.Conn
+	securityLabel string
 	credentials.AuthInfo
 }
 
@@ -213,15 +217,59 @@ func (ci *credInfo) AuthType() string {
 // If the provided Context has no gRPC peer, ErrNoPeer is returned. If the
 // Context's peer is of the wrong type (i.e. contains no peer process
 // credentials), ErrNoCredentials will be returned.
-func FromContext(ctx context.Context) (*unix.Ucred, error) {
+func FromContext(ctx context.Context) (peercred.Creds, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return peercred.Creds{}, ErrNoPeer
+	}
+
+	if ci, ok := p.AuthInfo.(*credInfo); ok && ci.hasCreds {
+		return ci.creds, nil
+	}
+
+	return peercred.Creds{}, ErrNoCredentials
+}
+
+// TLSInfoFromContext extracts the TLS credentials.TLSInfo -- including the
+// peer's verified certificate chain -- from the given Context. This is only
+// available on the server side, for connections established with
+// TLSTransportCredentials or ReloadableTLSCredentials.
+//
+// If the provided Context has no gRPC peer, ErrNoPeer is returned. If the
+// connection wasn't secured with TLS, ErrNoCredentials will be returned.
+func TLSInfoFromContext(ctx context.Context) (credentials.TLSInfo, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return credentials.TLSInfo{}, ErrNoPeer
+	}
+
+	if ci, ok := p.AuthInfo.(*credInfo); ok {
+		if tlsInfo, ok := ci.AuthInfo.(credentials.TLSInfo); ok {
+			return tlsInfo, nil
+		}
+	}
+
+	return credentials.TLSInfo{}, ErrNoCredentials
+}
+
+// SecurityLabelFromContext extracts the peer's SELinux/AppArmor/Smack
+// security label, if any, from the given Context. The label is only
+// available on Linux, and only when the kernel and active LSM populate
+// SO_PEERSEC for the underlying connection; otherwise it's the empty
+// string.
+//
+// If the provided Context has no gRPC peer, ErrNoPeer is returned. If the
+// Context's peer is of the wrong type (i.e. contains no peer process
+// credentials), ErrNoCredentials will be returned.
+func SecurityLabelFromContext(ctx context.Context) (string, error) {
 	p, ok := peer.FromContext(ctx)
 	if !ok {
-		return nil, ErrNoPeer
+		return "", ErrNoPeer
 	}
 
 	if ci, ok := p.AuthInfo.(*credInfo); ok {
-		return ci.ucred, nil
+		return ci.securityLabel, nil
 	}
 
-	return nil, ErrNoCredentials
+	return "", ErrNoCredentials
 }
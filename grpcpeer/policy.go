@@ -0,0 +1,144 @@
+// Copyright © 2023 Timothy E. Peoples
+
+package grpcpeer
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"toolman.org/net/peercred"
+)
+
+// Policy describes the peer-credential requirements enforced by the
+// interceptors returned by NewUnaryServerInterceptor and
+// NewStreamServerInterceptor.
+//
+// An empty Policy allows every caller through; non-empty AllowedUIDs and/or
+// AllowedGIDs restrict access to the listed IDs, both checks (when present)
+// must pass, and Check -- if set -- gets the final say.
+type Policy struct {
+	// AllowedUIDs, when non-empty, restricts access to callers whose peer
+	// Uid appears in this list.
+	AllowedUIDs []uint32
+
+	// AllowedGIDs, when non-empty, restricts access to callers whose peer
+	// Gid, or one of their supplementary groups, appears in this list.
+	// Supplementary groups are resolved by reading /proc/<pid>/status and
+	// are therefore only available on Linux.
+	AllowedGIDs []uint32
+
+	// Check, when non-nil, is consulted after the UID/GID checks above
+	// pass (or are skipped because the corresponding list is empty). It
+	// may inspect arbitrary additional state -- e.g. /proc/<pid>/exe or
+	// an external ACL -- to make the final accept/reject decision. method
+	// is the full gRPC method name being invoked. A plain (non-status)
+	// error is reported to the caller as codes.PermissionDenied; Check may
+	// instead return a *status.Status-backed error to report a different
+	// code (e.g. codes.Internal for an ACL lookup failure).
+	Check func(ctx context.Context, creds peercred.Creds, method string) error
+
+	// Methods, when non-empty, overrides the policy above for specific
+	// methods. A call whose full method name (e.g. "/pkg.Service/Method")
+	// matches a key here is evaluated solely against that entry's Policy;
+	// calls to any other method fall back to the fields above.
+	Methods map[string]Policy
+}
+
+// resolve returns the Policy that actually applies to method: the matching
+// entry in p.Methods if there is one, otherwise p itself.
+func (p Policy) resolve(method string) Policy {
+	if mp, ok := p.Methods[method]; ok {
+		return mp
+	}
+	return p
+}
+
+// allows evaluates creds against p for the named method, returning nil if
+// the call is permitted or a descriptive error if it's not.
+func (p Policy) allows(ctx context.Context, creds peercred.Creds, method string) error {
+	p = p.resolve(method)
+
+	if len(p.AllowedUIDs) > 0 && !containsUint32(p.AllowedUIDs, uint32(creds.Uid)) {
+		return status.Errorf(codes.PermissionDenied, "uid %d is not permitted to call %s", creds.Uid, method)
+	}
+
+	if len(p.AllowedGIDs) > 0 {
+		gids, err := supplementaryGIDs(creds.Pid)
+		if err != nil {
+			return status.Errorf(codes.Internal, "resolving supplementary groups: %v", err)
+		}
+
+		if !containsUint32(p.AllowedGIDs, uint32(creds.Gid)) && !anyContainsUint32(p.AllowedGIDs, gids) {
+			return status.Errorf(codes.PermissionDenied, "gid %d is not permitted to call %s", creds.Gid, method)
+		}
+	}
+
+	if p.Check != nil {
+		if err := p.Check(ctx, creds, method); err != nil {
+			if _, ok := status.FromError(err); ok {
+				return err
+			}
+			return status.Errorf(codes.PermissionDenied, "%v", err)
+		}
+	}
+
+	return nil
+}
+
+func containsUint32(list []uint32, v uint32) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func anyContainsUint32(list, vs []uint32) bool {
+	for _, v := range vs {
+		if containsUint32(list, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewUnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// enforces policy against the peer credentials of each incoming unary call,
+// extracted via FromContext, before invoking the handler. Calls with no
+// peer credentials are rejected with codes.Unauthenticated; calls that fail
+// policy are rejected with codes.PermissionDenied.
+func NewUnaryServerInterceptor(policy Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := enforce(ctx, policy, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewStreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// enforces policy against the peer credentials of each incoming streaming
+// call, extracted via FromContext, before invoking the handler. Calls with
+// no peer credentials are rejected with codes.Unauthenticated; calls that
+// fail policy are rejected with codes.PermissionDenied.
+func NewStreamServerInterceptor(policy Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := enforce(ss.Context(), policy, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func enforce(ctx context.Context, policy Policy, method string) error {
+	creds, err := FromContext(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return policy.allows(ctx, creds, method)
+}
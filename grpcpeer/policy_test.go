@@ -0,0 +1,148 @@
+// Copyright © 2023 Timothy E. Peoples
+
+package grpcpeer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"toolman.org/net/peercred"
+)
+
+func TestPolicyAllows(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		policy Policy
+		creds  peercred.Creds
+		method string
+		code   codes.Code
+	}{
+		{
+			name:   "empty policy allows everyone",
+			policy: Policy{},
+			creds:  peercred.Creds{Pid: -1, Uid: 99, Gid: 99},
+			method: "/pkg.Svc/Method",
+			code:   codes.OK,
+		},
+		{
+			name:   "uid in allow list",
+			policy: Policy{AllowedUIDs: []uint32{1000, 1001}},
+			creds:  peercred.Creds{Pid: -1, Uid: 1000, Gid: 99},
+			method: "/pkg.Svc/Method",
+			code:   codes.OK,
+		},
+		{
+			name:   "uid not in allow list",
+			policy: Policy{AllowedUIDs: []uint32{1000}},
+			creds:  peercred.Creds{Pid: -1, Uid: 1001, Gid: 99},
+			method: "/pkg.Svc/Method",
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "gid matches primary gid",
+			policy: Policy{AllowedGIDs: []uint32{100}},
+			creds:  peercred.Creds{Pid: -1, Uid: 1, Gid: 100},
+			method: "/pkg.Svc/Method",
+			code:   codes.OK,
+		},
+		{
+			name:   "gid matches neither primary nor supplementary",
+			policy: Policy{AllowedGIDs: []uint32{100}},
+			creds:  peercred.Creds{Pid: -1, Uid: 1, Gid: 200},
+			method: "/pkg.Svc/Method",
+			code:   codes.PermissionDenied,
+		},
+		{
+			name: "per-method override replaces top-level policy",
+			policy: Policy{
+				AllowedUIDs: []uint32{1000},
+				Methods: map[string]Policy{
+					"/pkg.Svc/Open": {AllowedUIDs: []uint32{1}},
+				},
+			},
+			creds:  peercred.Creds{Pid: -1, Uid: 1, Gid: 99},
+			method: "/pkg.Svc/Open",
+			code:   codes.OK,
+		},
+		{
+			name: "per-method override still denies unlisted uid",
+			policy: Policy{
+				Methods: map[string]Policy{
+					"/pkg.Svc/Open": {AllowedUIDs: []uint32{1}},
+				},
+			},
+			creds:  peercred.Creds{Pid: -1, Uid: 2, Gid: 99},
+			method: "/pkg.Svc/Open",
+			code:   codes.PermissionDenied,
+		},
+		{
+			name: "Check returning a plain error maps to PermissionDenied",
+			policy: Policy{
+				Check: func(context.Context, peercred.Creds, string) error {
+					return errors.New("nope")
+				},
+			},
+			creds:  peercred.Creds{Pid: -1, Uid: 1, Gid: 1},
+			method: "/pkg.Svc/Method",
+			code:   codes.PermissionDenied,
+		},
+		{
+			name: "Check returning a *status.Status error preserves its code",
+			policy: Policy{
+				Check: func(context.Context, peercred.Creds, string) error {
+					return status.Error(codes.Internal, "acl lookup failed")
+				},
+			},
+			creds:  peercred.Creds{Pid: -1, Uid: 1, Gid: 1},
+			method: "/pkg.Svc/Method",
+			code:   codes.Internal,
+		},
+		{
+			name: "Check is only consulted after UID/GID checks pass",
+			policy: Policy{
+				AllowedUIDs: []uint32{1000},
+				Check: func(context.Context, peercred.Creds, string) error {
+					t.Fatal("Check should not run when the UID check already failed")
+					return nil
+				},
+			},
+			creds:  peercred.Creds{Pid: -1, Uid: 1, Gid: 1},
+			method: "/pkg.Svc/Method",
+			code:   codes.PermissionDenied,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.allows(context.Background(), tt.creds, tt.method)
+			if tt.code == codes.OK {
+				if err != nil {
+					t.Fatalf("allows() = %v, want nil", err)
+				}
+				return
+			}
+
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("allows() code = %v, want %v (err: %v)", got, tt.code, err)
+			}
+		})
+	}
+}
+
+func TestPolicyResolve(t *testing.T) {
+	open := Policy{AllowedUIDs: []uint32{1}}
+	p := Policy{
+		AllowedUIDs: []uint32{1000},
+		Methods:     map[string]Policy{"/pkg.Svc/Open": open},
+	}
+
+	if got := p.resolve("/pkg.Svc/Open"); !containsUint32(got.AllowedUIDs, 1) {
+		t.Fatalf("resolve() = %+v, want method override %+v", got, open)
+	}
+
+	if got := p.resolve("/pkg.Svc/Other"); !containsUint32(got.AllowedUIDs, 1000) {
+		t.Fatalf("resolve() = %+v, want base policy %+v", got, p)
+	}
+}
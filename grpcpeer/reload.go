@@ -0,0 +1,169 @@
+// Copyright © 2023 Timothy E. Peoples
+
+package grpcpeer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// ReloadOptions names the certificate, key and CA files backing a set of
+// reloadable mTLS credentials created by ReloadableTLSCredentials or
+// ReloadableTLSDialCredentials, and configures how they're kept fresh.
+//
+// A *ReloadOptions must not be copied after it's passed to either
+// constructor.
+type ReloadOptions struct {
+	// CertFile and KeyFile name the PEM-encoded certificate and private
+	// key presented during the TLS handshake.
+	CertFile, KeyFile string
+
+	// CAFile names the PEM-encoded certificate pool used to verify the
+	// remote peer's certificate for mutual TLS.
+	CAFile string
+
+	// Signal, if non-nil, triggers a Reload whenever the current process
+	// receives it. It defaults to syscall.SIGHUP.
+	Signal os.Signal
+
+	cfg  atomic.Pointer[tls.Config]
+	once sync.Once
+}
+
+// Reload re-reads CertFile, KeyFile and CAFile, and -- if and only if they
+// parse successfully -- atomically swaps them in as the configuration used
+// for all subsequent TLS handshakes. If reading or parsing fails, the
+// previously loaded configuration, if any, remains in effect and the error
+// is returned.
+func (o *ReloadOptions) Reload() error {
+	cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	pem, err := os.ReadFile(o.CAFile)
+	if err != nil {
+		return err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("%s: contains no PEM certificates", o.CAFile)
+	}
+
+	o.cfg.Store(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		RootCAs:      pool,
+	})
+
+	return nil
+}
+
+// watch starts the signal-driven Reload goroutine, if it hasn't been
+// started already for this *ReloadOptions.
+func (o *ReloadOptions) watch() {
+	o.once.Do(func() {
+		sig := o.Signal
+		if sig == nil {
+			sig = syscall.SIGHUP
+		}
+
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, sig)
+
+		go func() {
+			for range ch {
+				o.Reload()
+			}
+		}()
+	})
+}
+
+// ReloadableTLSCredentials returns a grpc.ServerOption requiring mutual TLS
+// authentication using the certificate, key and CA pool named by opts. The
+// files are read immediately and again every time opts.Reload is called or
+// opts.Signal (SIGHUP by default) is received by this process, so a
+// long-lived server can rotate certificates without restarting.
+//
+// As with TLSTransportCredentials, the peercred.Conn's Creds are still
+// merged into the Context available via FromContext; the peer's verified
+// certificate chain is available via TLSInfoFromContext.
+func ReloadableTLSCredentials(opts *ReloadOptions) (grpc.ServerOption, error) {
+	if err := opts.Reload(); err != nil {
+		return nil, err
+	}
+	opts.watch()
+
+	return grpc.Creds(&peerCredentials{tcreds: &reloadableCreds{opts: opts}}), nil
+}
+
+// ReloadableTLSDialCredentials is the client-side counterpart to
+// ReloadableTLSCredentials, returning a grpc.DialOption that presents the
+// certificate, key and CA pool named by opts and reloads them the same way.
+func ReloadableTLSDialCredentials(opts *ReloadOptions) (grpc.DialOption, error) {
+	if err := opts.Reload(); err != nil {
+		return nil, err
+	}
+	opts.watch()
+
+	return grpc.WithTransportCredentials(&peerCredentials{tcreds: &reloadableCreds{opts: opts}}), nil
+}
+
+// reloadableCreds implements credentials.TransportCredentials by building a
+// fresh TLS credentials.TransportCredentials, from whatever *tls.Config its
+// *ReloadOptions most recently loaded, for every handshake. This is what
+// makes certificate rotation take effect for new connections without a
+// restart.
+type reloadableCreds struct {
+	opts *ReloadOptions
+
+	serverName atomic.Value // string, set by OverrideServerName
+}
+
+func (rc *reloadableCreds) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return rc.current().ClientHandshake(ctx, authority, conn)
+}
+
+func (rc *reloadableCreds) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return rc.current().ServerHandshake(conn)
+}
+
+func (rc *reloadableCreds) Info() credentials.ProtocolInfo {
+	return rc.current().Info()
+}
+
+func (rc *reloadableCreds) Clone() credentials.TransportCredentials {
+	c := &reloadableCreds{opts: rc.opts}
+	if sn, ok := rc.serverName.Load().(string); ok {
+		c.serverName.Store(sn)
+	}
+	return c
+}
+
+func (rc *reloadableCreds) OverrideServerName(s string) error {
+	rc.serverName.Store(s)
+	return nil
+}
+
+// current builds a credentials.TransportCredentials from the *tls.Config
+// most recently loaded by rc.opts, applying any OverrideServerName value.
+func (rc *reloadableCreds) current() credentials.TransportCredentials {
+	cfg := rc.opts.cfg.Load().Clone()
+	if sn, ok := rc.serverName.Load().(string); ok && sn != "" {
+		cfg.ServerName = sn
+	}
+	return credentials.NewTLS(cfg)
+}
@@ -20,9 +20,10 @@
 // IN THE SOFTWARE.
 // -----------------------------------------------------------------------------
 
-// Package peercred provides Listener - a net.Listener implementation leveraging
-// the Linux SO_PEERCRED socket option to acquire the PID, UID, and GID of the
-// foreign process connected to each socket. According to the socket(7) manual,
+// Package peercred provides Listener - a net.Listener implementation that
+// acquires the PID, UID, and GID of the foreign process connected to each
+// socket (via SO_PEERCRED on Linux, getpeereid(2) on Darwin and FreeBSD).
+// According to the socket(7) manual,
 //
 //	This is possible only for connected AF_UNIX stream
 //	sockets and AF_UNIX stream and datagram socket pairs
@@ -51,11 +52,13 @@
 //	    return err
 //	}
 //
-//	// conn.Ucred has fields Pid, Uid and Gid
-//	fmt.Printf("Client PID=%d UID=%d\n", conn.Ucred.Pid, conn.Ucred.Uid)
+//	// conn.Creds has fields Pid, Uid and Gid
+//	fmt.Printf("Client PID=%d UID=%d\n", conn.Creds.Pid, conn.Creds.Uid)
 //
-// NOTE: Currently, this package only works on Linux.
-// MacOS and FreeBSD are on the todo list. Windows isn't (nor are other OSs).
+// peercred.Listener is supported on Linux, Darwin and FreeBSD. On Linux,
+// peer credentials are obtained via SO_PEERCRED; on Darwin and FreeBSD,
+// via getpeereid(2) (Darwin additionally looks up the peer's PID via
+// SOL_LOCAL/LOCAL_PEERPID). No other OSs are supported.
 package peercred // import "toolman.org/net/peercred"
 
 import (
@@ -75,10 +78,11 @@ const ErrAddrInUse = unix.EADDRINUSE
 // (i.e. PID, UID, GID) of the foreign process connected to each socket. Since
 // the underlying features making this possible are only available for "unix"
 // sockets, no "network" argument is required here ("unix" is implied). The
-// acquired peer credentials are made available through the "Ucred" member of
+// acquired peer credentials are made available through the "Creds" member of
 // the *Conn returned by AcceptPeerCred.
 //
-// See 'SO_PEERCRED' in socket(7) for further details.
+// See 'SO_PEERCRED' in socket(7) (Linux) and 'getpeereid' in unix(4) (Darwin,
+// FreeBSD) for further details.
 type Listener struct {
 	once sync.Once
 	net.Listener
@@ -86,8 +90,14 @@ type Listener struct {
 
 // Listen returns a new *Listener listening on the Unix domain socket addr.
 func Listen(ctx context.Context, addr string) (*Listener, error) {
+	return listenNetwork(ctx, "unix", addr)
+}
+
+// listenNetwork is the shared implementation behind Listen and ListenURL's
+// "unix" and "unixpacket" schemes.
+func listenNetwork(ctx context.Context, network, addr string) (*Listener, error) {
 	lc := new(net.ListenConfig)
-	l, err := lc.Listen(ctx, "unix", addr)
+	l, err := lc.Listen(ctx, network, addr)
 	if err != nil {
 		return nil, chkAddrInUseError(err)
 	}
@@ -95,6 +105,15 @@ func Listen(ctx context.Context, addr string) (*Listener, error) {
 	return &Listener{Listener: l}, nil
 }
 
+// FromUnixConn extracts peer process credentials from c the same way
+// Listener.AcceptPeerCred does, returning a *Conn wrapping it. This is for
+// callers that obtain a *net.UnixConn some other way -- e.g. from
+// net.FileConn, socketpair(2), or a third-party listener -- rather than
+// through a peercred.Listener.
+func FromUnixConn(c *net.UnixConn) (*Conn, error) {
+	return newConn(c)
+}
+
 // Close is a wrapper that calls the underlying net.Listener's Close method
 // once and only once regardless how many times this method is called.
 //
@@ -167,40 +186,7 @@ func (pcl *Listener) accept(ctx context.Context) (*Conn, error) {
 		return nil, err
 	}
 
-	pcc := &Conn{Conn: conn}
-
-	uc, ok := conn.(*net.UnixConn)
-	if !ok {
-		return pcc, nil
-	}
-
-	rc, err := uc.SyscallConn()
-	if err != nil {
-		return nil, err
-	}
-
-	var ucred *unix.Ucred
-	cerr := rc.Control(func(fd uintptr) {
-		ucred, err = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
-	})
-
-	if cerr != nil || err != nil {
-		if err == nil {
-			err = cerr
-		}
-		return nil, err
-	}
-
-	pcc.Ucred = ucred
-
-	return pcc, nil
-}
-
-// Conn is a net.Conn containing the process credentials for the client
-// side of a Unix domain socket connection.
-type Conn struct {
-	Ucred *unix.Ucred
-	net.Conn
+	return newConn(conn)
 }
 
 func asErrno(err error) unix.Errno {
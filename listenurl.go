@@ -0,0 +1,123 @@
+// Copyright © 2023 Timothy E. Peoples
+
+package peercred
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ListenURL parses spec -- a listener specification of the form
+// "proto://address" -- and returns the *Listener(s) it describes, keyed by
+// their resolved socket name. This mirrors the listener-spec convention
+// used by containerd and docker, letting operators switch between
+// socket-activated and self-bound sockets purely via configuration.
+//
+// Three forms of spec are recognized:
+//
+//	unix://address, unixpacket://address
+//	    Binds a new Unix domain socket via Listen (or, for unixpacket, its
+//	    SOCK_SEQPACKET equivalent). The result is a single entry keyed by
+//	    address.
+//
+//	systemd:, systemd:name, fd://name
+//	    Invokes SDListenNames to acquire the socket(s) passed to this
+//	    process by systemd socket activation. If name is given, the
+//	    result is filtered down to the single Listener whose
+//	    FileDescriptorName is name; any other activated sockets are
+//	    closed.
+//
+//	socketpair:
+//	    Returns a single in-process, already-connected Listener/Conn pair
+//	    for tests -- see SocketpairDial -- keyed by "socketpair".
+func ListenURL(ctx context.Context, spec string) (map[string]*Listener, error) {
+	scheme, rest, err := splitSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	switch scheme {
+	case "unix", "unixpacket":
+		lis, err := listenNetwork(ctx, scheme, rest)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]*Listener{rest: lis}, nil
+
+	case "systemd", "fd":
+		return sdListen(rest)
+
+	case "socketpair":
+		lis, err := newSocketpairListener()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]*Listener{"socketpair": lis}, nil
+	}
+
+	return nil, fmt.Errorf("peercred: unrecognized listener scheme %q", scheme)
+}
+
+// ListenOne is a convenience wrapper around ListenURL for specs that
+// resolve to exactly one *Listener. It's an error for spec to resolve to
+// zero or multiple Listeners; in the latter case, all of them are closed.
+func ListenOne(ctx context.Context, spec string) (*Listener, error) {
+	lismap, err := ListenURL(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lismap) == 1 {
+		for _, lis := range lismap {
+			return lis, nil
+		}
+	}
+
+	for _, lis := range lismap {
+		lis.Close()
+	}
+
+	if len(lismap) == 0 {
+		return nil, fmt.Errorf("peercred: spec %q matched no listeners", spec)
+	}
+	return nil, fmt.Errorf("peercred: spec %q matched multiple listeners", spec)
+}
+
+// sdListen acquires the systemd activated socket(s) provided to this
+// process, filtering them down to the one named fdname if it's non-empty.
+func sdListen(fdname string) (map[string]*Listener, error) {
+	lismap, err := SDListenNames()
+	if err != nil {
+		return nil, err
+	}
+
+	if fdname == "" {
+		return lismap, nil
+	}
+
+	lis, ok := lismap[fdname]
+	for n, l := range lismap {
+		if n != fdname {
+			l.Close()
+		}
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("no systemd activated socket named %q", fdname)
+	}
+
+	return map[string]*Listener{fdname: lis}, nil
+}
+
+// splitSpec parses a "proto://address" or "proto:opaque" listener spec
+// into its scheme and remainder.
+func splitSpec(spec string) (scheme, rest string, err error) {
+	if i := strings.Index(spec, "://"); i >= 0 {
+		return spec[:i], spec[i+3:], nil
+	}
+	if i := strings.Index(spec, ":"); i >= 0 {
+		return spec[:i], spec[i+1:], nil
+	}
+	return "", "", fmt.Errorf("peercred: malformed listener spec %q", spec)
+}
@@ -0,0 +1,99 @@
+// Copyright © 2023 Timothy E. Peoples
+
+package peercred
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenURL(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tt := range []struct {
+		name    string
+		spec    string
+		wantKey string
+		wantErr bool
+	}{
+		{
+			name:    "unix",
+			spec:    "unix://" + filepath.Join(dir, "unix.sock"),
+			wantKey: filepath.Join(dir, "unix.sock"),
+		},
+		{
+			name:    "unixpacket",
+			spec:    "unixpacket://" + filepath.Join(dir, "unixpacket.sock"),
+			wantKey: filepath.Join(dir, "unixpacket.sock"),
+		},
+		{
+			name:    "socketpair",
+			spec:    "socketpair:",
+			wantKey: "socketpair",
+		},
+		{
+			name:    "systemd with no activation env vars",
+			spec:    "systemd:",
+			wantErr: true,
+		},
+		{
+			name:    "fd with no activation env vars",
+			spec:    "fd://myname",
+			wantErr: true,
+		},
+		{
+			name:    "unrecognized scheme",
+			spec:    "bogus://whatever",
+			wantErr: true,
+		},
+		{
+			name:    "malformed spec",
+			spec:    "no-scheme-here",
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Unsetenv("LISTEN_PID")
+			os.Unsetenv("LISTEN_FDS")
+			os.Unsetenv("LISTEN_FDNAMES")
+
+			lismap, err := ListenURL(context.Background(), tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					for _, lis := range lismap {
+						lis.Close()
+					}
+					t.Fatalf("ListenURL(%q) = %v, nil; want an error", tt.spec, lismap)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ListenURL(%q) returned unexpected error: %v", tt.spec, err)
+			}
+			defer func() {
+				for _, lis := range lismap {
+					lis.Close()
+				}
+			}()
+
+			if _, ok := lismap[tt.wantKey]; !ok {
+				t.Fatalf("ListenURL(%q) = %v, want key %q", tt.spec, lismap, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestListenOne(t *testing.T) {
+	lis, err := ListenOne(context.Background(), "socketpair:")
+	if err != nil {
+		t.Fatalf("ListenOne(socketpair:) returned unexpected error: %v", err)
+	}
+	defer lis.Close()
+
+	if _, err := ListenOne(context.Background(), "bogus://whatever"); err == nil {
+		t.Fatal("ListenOne(bogus://whatever) = nil, want an error")
+	}
+}
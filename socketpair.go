@@ -0,0 +1,116 @@
+// Copyright © 2023 Timothy E. Peoples
+
+package peercred
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// ErrNotSocketpair is returned by SocketpairDial when passed a *Listener
+// that wasn't created via ListenURL's "socketpair:" scheme.
+var ErrNotSocketpair = errors.New("peercred: listener was not created with the socketpair scheme")
+
+// SocketpairDial returns the client side of a *Listener created by
+// ListenURL's "socketpair:" scheme, for tests that need both ends of an
+// in-process connection; the corresponding server side is obtained as
+// usual via lis.AcceptPeerCred. Each side may only be claimed once.
+func SocketpairDial(lis *Listener) (net.Conn, error) {
+	spl, ok := lis.Listener.(*socketpairListener)
+	if !ok {
+		return nil, ErrNotSocketpair
+	}
+	return spl.dial()
+}
+
+// newSocketpairListener creates a connected socketpair(2) pair and wraps it
+// in a *Listener whose sole Accept returns the server side.
+func newSocketpairListener() (*Listener, error) {
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err := fileConn(fds[0], "peercred-socketpair-server")
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := fileConn(fds[1], "peercred-socketpair-client")
+	if err != nil {
+		srv.Close()
+		return nil, err
+	}
+
+	return &Listener{Listener: &socketpairListener{srv: srv, cli: cli}}, nil
+}
+
+func fileConn(fd int, name string) (net.Conn, error) {
+	f := os.NewFile(uintptr(fd), name)
+	defer f.Close()
+	return net.FileConn(f)
+}
+
+// socketpairListener is an in-process net.Listener wrapping a connected
+// socketpair(2) pair: Accept returns the server side exactly once, and
+// SocketpairDial returns the client side exactly once.
+type socketpairListener struct {
+	mu  sync.Mutex
+	srv net.Conn
+	cli net.Conn
+}
+
+func (l *socketpairListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.srv == nil {
+		return nil, errors.New("peercred: socketpair already accepted or closed")
+	}
+
+	c := l.srv
+	l.srv = nil
+	return c, nil
+}
+
+func (l *socketpairListener) dial() (net.Conn, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cli == nil {
+		return nil, errors.New("peercred: socketpair client already taken or closed")
+	}
+
+	c := l.cli
+	l.cli = nil
+	return c, nil
+}
+
+func (l *socketpairListener) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var err error
+	if l.srv != nil {
+		err = l.srv.Close()
+		l.srv = nil
+	}
+	if l.cli != nil {
+		if cerr := l.cli.Close(); err == nil {
+			err = cerr
+		}
+		l.cli = nil
+	}
+	return err
+}
+
+func (l *socketpairListener) Addr() net.Addr { return socketpairAddr{} }
+
+type socketpairAddr struct{}
+
+func (socketpairAddr) Network() string { return "socketpair" }
+func (socketpairAddr) String() string  { return "socketpair" }
@@ -0,0 +1,70 @@
+// Copyright © 2023 Timothy E. Peoples
+
+package peercred
+
+import (
+	"errors"
+	"net"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestFromUnixConn(t *testing.T) {
+	lis, err := newSocketpairListener()
+	if err != nil {
+		t.Fatalf("newSocketpairListener() returned unexpected error: %v", err)
+	}
+	defer lis.Close()
+
+	cli, err := SocketpairDial(lis)
+	if err != nil {
+		t.Fatalf("SocketpairDial() returned unexpected error: %v", err)
+	}
+	defer cli.Close()
+
+	uc, ok := cli.(*net.UnixConn)
+	if !ok {
+		t.Fatalf("SocketpairDial() returned a %T, want *net.UnixConn", cli)
+	}
+
+	conn, err := FromUnixConn(uc)
+	if err != nil {
+		t.Fatalf("FromUnixConn() returned unexpected error: %v", err)
+	}
+
+	wantPid := int32(os.Getpid())
+	if runtime.GOOS == "freebsd" {
+		// Creds.Pid is always -1 on FreeBSD.
+		wantPid = -1
+	}
+	if got, want := conn.Creds.Pid, wantPid; got != want {
+		t.Errorf("conn.Creds.Pid = %d, want %d", got, want)
+	}
+	if got, want := conn.Creds.Uid, int32(os.Getuid()); got != want {
+		t.Errorf("conn.Creds.Uid = %d, want %d", got, want)
+	}
+	if got, want := conn.Creds.Gid, int32(os.Getgid()); got != want {
+		t.Errorf("conn.Creds.Gid = %d, want %d", got, want)
+	}
+}
+
+func TestPeerSecurityLabel(t *testing.T) {
+	lis, err := newSocketpairListener()
+	if err != nil {
+		t.Fatalf("newSocketpairListener() returned unexpected error: %v", err)
+	}
+	defer lis.Close()
+
+	srv, err := lis.AcceptPeerCred()
+	if err != nil {
+		t.Fatalf("AcceptPeerCred() returned unexpected error: %v", err)
+	}
+
+	// There's no active LSM in the test environment, so the only
+	// contractual guarantee is "label with no error, or ErrNotSupported";
+	// either is a pass.
+	if _, err := srv.PeerSecurityLabel(); err != nil && !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("PeerSecurityLabel() returned unexpected error: %v", err)
+	}
+}